@@ -0,0 +1,42 @@
+package config
+
+// Configuration holds the crane agent's runtime configuration, assembled from
+// environment variables and defaults at startup.
+type Configuration struct {
+	IngressRootDomain string
+	FieldManagerName  string
+	ForceOnConflicts  bool
+
+	// DefaultIngressClassName is used to populate spec.ingressClassName on
+	// IngressApplyConfiguration when a deployment does not specify one
+	// explicitly. Leave empty to keep using the legacy
+	// kubernetes.io/ingress.class annotation.
+	DefaultIngressClassName string
+	// IngressClassControllerName is the controller string the default
+	// ingress class is expected to match, e.g. "traefik.io/ingress-controller"
+	// or "k8s.io/ingress-nginx". When set, the named IngressClass is looked up
+	// and spec.ingressClassName is only used if its spec.controller matches,
+	// otherwise the legacy annotation is used. Leave empty to skip the check.
+	IngressClassControllerName string
+
+	// IngressController selects the default IngressController backend
+	// (see pkg/crane/k8s.IngressController) used when a deployment doesn't
+	// set DeployIngressOptions.Controller explicitly. One of "nginx",
+	// "traefik", "contour", "haproxy", "kong". Defaults to "nginx".
+	IngressController string
+
+	// RoutingBackend picks which facade k8s.Deploy uses to expose a
+	// container: "ingress" provisions a networking.k8s.io/v1 Ingress (the
+	// default), "gateway" provisions a gateway.networking.k8s.io/v1
+	// HTTPRoute against an existing Gateway instead.
+	RoutingBackend string
+
+	// DefaultTLSIssuerName, DefaultTLSIssuerKind ("ClusterIssuer" or
+	// "Issuer") and DefaultTLSChallengeType ("http01" or "dns01") seed
+	// DeployIngressOptions.TLSConfig for deployments that don't set it
+	// explicitly, so operators can configure a per-team or org-wide issuer
+	// once instead of on every deployment.
+	DefaultTLSIssuerName    string
+	DefaultTLSIssuerKind    string
+	DefaultTLSChallengeType string
+}
@@ -0,0 +1,45 @@
+package k8s
+
+import "context"
+
+// Deploy exposes routing as a single entrypoint so callers don't need to
+// know which facade is in play: it dispatches to the Gateway API HTTPRoute
+// facade when config.Configuration.RoutingBackend is "gateway", and to the
+// legacy networking.k8s.io/v1 Ingress facade otherwise (the default).
+func Deploy(ctx context.Context, client *Client, options *DeployIngressOptions) error {
+	if client.appConfig.RoutingBackend == "gateway" {
+		return newGatewayRoute(ctx, client).deployRoute(routeOptionsFromIngress(options))
+	}
+	return newIngress(ctx, client).deployIngress(options)
+}
+
+// Delete is Deploy's counterpart: it removes the Ingress or HTTPRoute
+// previously provisioned for name, dispatching on the same
+// config.Configuration.RoutingBackend toggle.
+func Delete(ctx context.Context, client *Client, namespace, name string) error {
+	if client.appConfig.RoutingBackend == "gateway" {
+		return newGatewayRoute(ctx, client).deleteRoute(namespace, name)
+	}
+	return newIngress(ctx, client).deleteIngress(namespace, name)
+}
+
+// routeOptionsFromIngress carries the fields DeployIngressOptions and
+// DeployRouteOptions have in common over to the gateway facade, so callers
+// only have to build one options struct regardless of RoutingBackend.
+func routeOptionsFromIngress(options *DeployIngressOptions) *DeployRouteOptions {
+	return &DeployRouteOptions{
+		namespace:        options.namespace,
+		containerName:    options.containerName,
+		routeName:        options.ingressName,
+		ingressHost:      options.ingressHost,
+		uploadLimit:      options.uploadLimit,
+		ports:            options.ports,
+		proxyHeaders:     options.proxyHeaders,
+		allowedHeaders:   options.allowedHeaders,
+		labels:           options.labels,
+		annotations:      options.annotations,
+		GatewayName:      options.GatewayName,
+		GatewayNamespace: options.GatewayNamespace,
+		SectionName:      options.SectionName,
+	}
+}
@@ -0,0 +1,180 @@
+package k8s
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/rs/zerolog/log"
+	"golang.org/x/exp/maps"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+	gatewayapply "sigs.k8s.io/gateway-api/apis/applyconfiguration/apis/v1"
+	gatewayclientv1 "sigs.k8s.io/gateway-api/pkg/client/clientset/versioned/typed/apis/v1"
+
+	"github.com/dyrector-io/dyrectorio/golang/pkg/crane/config"
+)
+
+// gatewayRoute is the Gateway API counterpart of ingress: instead of a
+// networking.k8s.io/v1 Ingress it provisions an HTTPRoute bound to an
+// existing Gateway. Deploy (deploy.go) picks between the two facades based
+// on config.Configuration.RoutingBackend.
+type gatewayRoute struct {
+	ctx       context.Context
+	client    *Client
+	appConfig *config.Configuration
+}
+
+type DeployRouteOptions struct {
+	namespace, containerName, routeName, ingressHost, uploadLimit string
+	ports                                                         []int32
+	proxyHeaders                                                  bool
+	allowedHeaders                                                []string
+	labels                                                        map[string]string
+	annotations                                                   map[string]string
+
+	// GatewayName is the Gateway this HTTPRoute attaches to via parentRefs.
+	GatewayName string
+	// GatewayNamespace overrides the Gateway's namespace when it differs
+	// from the HTTPRoute's own namespace.
+	GatewayNamespace string
+	// SectionName pins the route to a single named listener on the Gateway,
+	// left empty to attach to every listener that allows it.
+	SectionName string
+}
+
+func newGatewayRoute(ctx context.Context, client *Client) *gatewayRoute {
+	return &gatewayRoute{ctx: ctx, client: client, appConfig: client.appConfig}
+}
+
+func (gr *gatewayRoute) deployRoute(options *DeployRouteOptions) error {
+	if options == nil {
+		return errors.New("gateway route deployment is nil")
+	}
+
+	if len(options.ports) == 0 {
+		return errors.New("empty ports, nothing to expose")
+	}
+
+	if options.GatewayName == "" {
+		return errors.New("no gateway provided to attach the HTTPRoute to")
+	}
+
+	client, err := gr.getHTTPRouteClient(options.namespace)
+	if err != nil {
+		log.Error().Err(err).Stack().Msg("Error with gateway route client")
+		return err
+	}
+
+	hostname, _, err := resolveRoutingHost(gr.appConfig, options.ingressHost, options.routeName, options.containerName, options.namespace)
+	if err != nil {
+		return err
+	}
+
+	parentRef := gatewayapply.ParentReference().
+		WithName(gatewayv1.ObjectName(options.GatewayName))
+	if options.GatewayNamespace != "" {
+		parentRef.WithNamespace(gatewayv1.Namespace(options.GatewayNamespace))
+	}
+	if options.SectionName != "" {
+		parentRef.WithSectionName(gatewayv1.SectionName(options.SectionName))
+	}
+
+	rule := gatewayapply.HTTPRouteRule().
+		WithBackendRefs(
+			gatewayapply.HTTPBackendRef().
+				WithName(gatewayv1.ObjectName(options.containerName)).
+				WithPort(gatewayv1.PortNumber(options.ports[0])),
+		).
+		WithFilters(buildHTTPRouteFilters(options, hostname)...)
+
+	annot := map[string]string{}
+	maps.Copy(annot, options.annotations)
+
+	labels := map[string]string{}
+	maps.Copy(labels, options.labels)
+
+	applyConfig := gatewayapply.HTTPRoute(options.containerName, options.namespace).
+		WithAnnotations(annot).
+		WithLabels(labels).
+		WithSpec(gatewayapply.HTTPRouteSpec().
+			WithParentRefs(parentRef).
+			WithHostnames(gatewayv1.Hostname(hostname)).
+			WithRules(rule))
+
+	route, err := client.Apply(gr.ctx, applyConfig, metav1.ApplyOptions{
+		FieldManager: gr.appConfig.FieldManagerName,
+		Force:        gr.appConfig.ForceOnConflicts,
+	})
+	if err != nil {
+		log.Error().Err(err).Str("httproute", options.containerName).Send()
+		return err
+	}
+
+	log.Info().Str("httproute", route.ObjectMeta.Name).Msg("HTTPRoute applied")
+	return nil
+}
+
+func (gr *gatewayRoute) deleteRoute(namespace, name string) error {
+	client, err := gr.getHTTPRouteClient(namespace)
+	if err != nil {
+		panic(err)
+	}
+
+	return client.Delete(gr.ctx, name, metav1.DeleteOptions{})
+}
+
+// buildHTTPRouteFilters translates the neutral proxyHeaders/CORS knobs shared
+// with DeployIngressOptions into HTTPRouteFilter entries, since Gateway API
+// has no ingress-controller-style annotations of its own. uploadLimit is not
+// translated: it only produces a warning log and no filter.
+//
+// proxyHeaders and allowedHeaders are kept as separate filters even though
+// both end up as header modifiers: proxyHeaders forwards request metadata to
+// the backend (RequestHeaderModifier), while allowedHeaders is a CORS policy
+// read by the client's browser (ResponseHeaderModifier) — conflating the two
+// previously meant CORS headers were sent on the request instead of the
+// response, and the forwarded X-Forwarded-* set was lost inside the CORS
+// value instead of being forwarded at all.
+func buildHTTPRouteFilters(opts *DeployRouteOptions, hostname string) []*gatewayapply.HTTPRouteFilterApplyConfiguration {
+	filters := []*gatewayapply.HTTPRouteFilterApplyConfiguration{}
+
+	if opts.proxyHeaders {
+		// X-Forwarded-Host is the one forwarded header with a value known at
+		// apply time; the client-address headers (X-Forwarded-For, X-Real-IP,
+		// etc.) are populated per-request by the Gateway implementation
+		// itself and can't be expressed as a static HTTPHeaderFilter value.
+		filters = append(filters, gatewayapply.HTTPRouteFilter().
+			WithType(gatewayv1.HTTPRouteFilterRequestHeaderModifier).
+			WithRequestHeaderModifier(gatewayapply.HTTPHeaderFilter().
+				WithSet(gatewayapply.HTTPHeader().
+					WithName(gatewayv1.HTTPHeaderName("X-Forwarded-Host")).
+					WithValue(hostname))))
+	}
+
+	if len(opts.allowedHeaders) > 0 {
+		filters = append(filters, gatewayapply.HTTPRouteFilter().
+			WithType(gatewayv1.HTTPRouteFilterResponseHeaderModifier).
+			WithResponseHeaderModifier(gatewayapply.HTTPHeaderFilter().
+				WithSet(gatewayapply.HTTPHeader().
+					WithName(gatewayv1.HTTPHeaderName("Access-Control-Allow-Headers")).
+					WithValue(strings.Join(opts.allowedHeaders, ", ")))))
+	}
+
+	if opts.uploadLimit != "" {
+		log.Warn().Str("uploadLimit", opts.uploadLimit).
+			Msg("uploadLimit is not supported on the gateway routing backend, ignoring")
+	}
+
+	return filters
+}
+
+func (gr *gatewayRoute) getHTTPRouteClient(namespace string) (gatewayclientv1.HTTPRouteInterface, error) {
+	clientset, err := gr.client.GetGatewayClientSet()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get gateway-api client: %w", err)
+	}
+
+	return clientset.GatewayV1().HTTPRoutes(namespace), nil
+}
@@ -4,12 +4,14 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"strconv"
 	"strings"
 
 	"github.com/rs/zerolog/log"
 	"golang.org/x/exp/maps"
 	v1 "k8s.io/api/networking/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/version"
 	applymetav1 "k8s.io/client-go/applyconfigurations/meta/v1"
 	netv1 "k8s.io/client-go/applyconfigurations/networking/v1"
 
@@ -21,6 +23,13 @@ import (
 
 const clusterIssuerDefault = "letsencrypt-prod"
 
+// minIngressClassMajor/Minor is the first Kubernetes release where
+// networking.k8s.io/v1 IngressClass and spec.ingressClassName are available.
+const (
+	minIngressClassMajor = 1
+	minIngressClassMinor = 18
+)
+
 // facade object for ingress management
 type ingress struct {
 	ctx       context.Context
@@ -36,7 +45,116 @@ type DeployIngressOptions struct {
 	allowedHeaders                                                  []string
 	labels                                                          map[string]string
 	annotations                                                     map[string]string
-	traefik                                                         bool
+
+	// IngressClassName, when set (or falling back to
+	// config.Configuration.DefaultIngressClassName), is applied as
+	// spec.ingressClassName instead of the legacy kubernetes.io/ingress.class
+	// annotation, provided the target cluster is new enough to support it.
+	// Leaving both unset keeps using the annotation-based path, even though
+	// the selected Controller has its own conventional class name: that name
+	// is only a fallback for annotations, not a signal that an IngressClass
+	// object with that name actually exists in the cluster.
+	IngressClassName string
+
+	// Controller selects the IngressController backend that builds the
+	// annotations and spec tweaks for this Ingress. One of "nginx",
+	// "traefik", "contour", "haproxy", "kong". Falls back to
+	// config.Configuration.IngressController, then "nginx".
+	Controller string
+
+	// FanoutStrategy controls how every port, ports[0] included, is exposed
+	// when there is more than one: "path" (default) gives each port its own
+	// "/svc-<port>/" path prefix under
+	// the same host, "subdomain" gives each port its own IngressRule under
+	// "<port>.<host>". Only relevant when len(ports) > 1.
+	FanoutStrategy string
+	// PortConfigs optionally overrides the path or host used for a given
+	// port instead of the FanoutStrategy-derived default, mirroring the
+	// per-port metadata on the deployed container's config.
+	PortConfigs []IngressPortConfig
+
+	// Path is the path exposed on the single-port rule and on each
+	// "subdomain" fanout rule, defaulting to "/". Ignored for ports
+	// overridden via PortConfigs or the "path" fanout strategy, which derive
+	// their own paths.
+	Path string
+	// PathType controls path matching semantics for every generated
+	// HTTPIngressPath, defaulting to PathTypePrefix.
+	PathType v1.PathType
+	// Rewrite, when set, rewrites the matched path before it reaches the
+	// backend (nginx.ingress.kubernetes.io/rewrite-target, a Traefik
+	// ReplacePathRegex middleware, haproxy.org/path-rewrite). Ignored with a
+	// warning on controllers with no Ingress-annotation-level equivalent
+	// (contour, kong).
+	Rewrite string
+	// PermanentRedirect, when set, issues a 301 to this URL instead of
+	// routing to the backend (nginx.ingress.kubernetes.io/permanent-redirect,
+	// a Traefik RedirectRegex middleware, haproxy.org/request-redirect).
+	// Ignored with a warning on controllers with no Ingress-annotation-level
+	// equivalent (contour, kong).
+	PermanentRedirect string
+
+	// TLSConfig selects the cert-manager Issuer and challenge used to
+	// provision the TLS certificate when tls is enabled. Unset fields fall
+	// back to config.Configuration's TLS defaults.
+	TLSConfig TLSConfig
+
+	// GatewayName, GatewayNamespace, and SectionName are only used when
+	// config.Configuration.RoutingBackend is "gateway": they carry the
+	// Gateway this deployment's HTTPRoute attaches to, see
+	// DeployRouteOptions for their meaning. Ignored for the default
+	// "ingress" backend.
+	GatewayName      string
+	GatewayNamespace string
+	SectionName      string
+}
+
+// IssuerKind distinguishes a cluster-scoped ClusterIssuer from a
+// namespace-scoped Issuer.
+type IssuerKind string
+
+const (
+	IssuerKindClusterIssuer IssuerKind = "ClusterIssuer"
+	IssuerKindIssuer        IssuerKind = "Issuer"
+)
+
+// ChallengeType is the ACME challenge cert-manager uses to prove domain
+// ownership.
+type ChallengeType string
+
+const (
+	ChallengeTypeHTTP01 ChallengeType = "http01"
+	ChallengeTypeDNS01  ChallengeType = "dns01"
+)
+
+// TLSConfig picks the cert-manager Issuer and ACME challenge for a
+// deployment's certificate, and whether it should cover the whole
+// "*.<root>" subdomain instead of just the deployment's own host.
+type TLSConfig struct {
+	IssuerName    string
+	IssuerKind    IssuerKind
+	ChallengeType ChallengeType
+	// Wildcard requests "*.<ingressRoot>" as the TLS certificate's host
+	// instead of the deployment's specific hostname, while the IngressRule
+	// itself keeps routing on the specific host.
+	Wildcard bool
+}
+
+// IngressPortConfig carries per-port routing overrides for containers that
+// expose more than one port (e.g. an app port plus a metrics endpoint).
+type IngressPortConfig struct {
+	Port int32
+	Path string
+	Host string
+}
+
+func findPortConfig(configs []IngressPortConfig, port int32) *IngressPortConfig {
+	for i := range configs {
+		if configs[i].Port == port {
+			return &configs[i]
+		}
+	}
+	return nil
 }
 
 func newIngress(ctx context.Context, client *Client) *ingress {
@@ -57,43 +175,72 @@ func (ing *ingress) deployIngress(options *DeployIngressOptions) error {
 		return errors.New("empty ports, nothing to expose")
 	}
 
-	var ingressRoot string
-	if options.ingressHost != "" {
-		ingressRoot = options.ingressHost
-	} else if ing.appConfig.IngressRootDomain != "" {
-		ingressRoot = ing.appConfig.IngressRootDomain
-	} else {
-		return fmt.Errorf("no ingress domain provided in deploy request or configuration")
+	ingressPath, ingressRoot, err := resolveRoutingHost(ing.appConfig, options.ingressHost, options.ingressName, options.containerName, options.namespace)
+	if err != nil {
+		return err
 	}
 
-	var ingressPath string
-	if options.ingressName != "" {
-		ingressPath = util.JoinV(".", options.ingressName, ingressRoot)
-	} else {
-		ingressPath = util.JoinV(".", options.containerName, options.namespace, ingressRoot)
-	}
-
-	spec := netv1.IngressSpec().
-		WithRules(
-			netv1.IngressRule().
-				WithHost(ingressPath).
-				WithHTTP(netv1.HTTPIngressRuleValue().WithPaths(
-					netv1.HTTPIngressPath().WithPath("/").
-						WithPathType(v1.PathTypeImplementationSpecific).
-						WithBackend(
-							netv1.IngressBackend().WithService(
-								netv1.IngressServiceBackend().
-									WithName(options.containerName).
-									WithPort(netv1.ServiceBackendPort().WithNumber(options.ports[0])),
-							),
-						),
-				)))
-	tlsConf := getTLSConfig(ingressPath, options.containerName, options.tls)
+	ing.resolveTLSConfig(options)
+
+	spec := netv1.IngressSpec().WithRules(buildIngressRules(options, ingressPath)...)
+	tlsConf := getTLSConfig(ingressPath, ingressRoot, options.containerName, options.tls, options.TLSConfig.Wildcard)
 	if tlsConf != nil {
 		spec.WithTLS(tlsConf)
 	}
 
-	annot := getIngressAnnotations(options)
+	controllerName := options.Controller
+	if controllerName == "" {
+		controllerName = ing.appConfig.IngressController
+	}
+	controller := resolveIngressController(controllerName)
+	controller.MutateSpec(spec)
+
+	if _, ok := controller.(traefikController); ok {
+		if err := ing.applyTraefikMiddlewares(options); err != nil {
+			return err
+		}
+	}
+	if _, ok := controller.(kongController); ok {
+		if err := ing.applyKongPlugins(options); err != nil {
+			return err
+		}
+	}
+
+	ingressClassName := options.IngressClassName
+	if ingressClassName == "" {
+		ingressClassName = ing.appConfig.DefaultIngressClassName
+	}
+
+	useIngressClassName := false
+	if ingressClassName != "" {
+		useIngressClassName, err = ing.supportsIngressClassName()
+		if err != nil {
+			log.Warn().Err(err).Msg("Unable to determine cluster version, falling back to ingress.class annotation")
+		}
+	}
+
+	if useIngressClassName {
+		matches, err := ing.ingressClassControllerMatches(ingressClassName)
+		if err != nil {
+			log.Warn().Err(err).Str("ingressClassName", ingressClassName).
+				Msg("Unable to verify IngressClass controller, falling back to ingress.class annotation")
+			useIngressClassName = false
+		} else if !matches {
+			log.Warn().Str("ingressClassName", ingressClassName).
+				Str("expectedController", ing.appConfig.IngressClassControllerName).
+				Msg("IngressClass controller does not match configuration, falling back to ingress.class annotation")
+			useIngressClassName = false
+		}
+	}
+
+	if useIngressClassName {
+		spec.WithIngressClassName(ingressClassName)
+	}
+
+	annot := controller.Annotations(options)
+	if useIngressClassName {
+		delete(annot, "kubernetes.io/ingress.class")
+	}
 	maps.Copy(annot, options.annotations)
 
 	labels := map[string]string{}
@@ -127,70 +274,196 @@ func (ing *ingress) deleteIngress(namespace, name string) error {
 	return client.Delete(ing.ctx, name, metav1.DeleteOptions{})
 }
 
-func getTLSConfig(ingressPath, containerName string, enabled bool) *netv1.IngressTLSApplyConfiguration {
-	if enabled {
-		return netv1.IngressTLS().
-			WithHosts(ingressPath).
-			WithSecretName(util.JoinV("-", containerName, "tls"))
+// resolveRoutingHost derives the externally visible hostname for a
+// deployment, shared by the Ingress and Gateway API HTTPRoute facades: an
+// explicit name takes the form "<name>.<root>", otherwise it falls back to
+// "<container>.<namespace>.<root>". It also returns the bare root domain,
+// needed to build wildcard TLS hosts.
+func resolveRoutingHost(appConfig *config.Configuration, ingressHost, name, containerName, namespace string) (host, root string, err error) {
+	if ingressHost != "" {
+		root = ingressHost
+	} else if appConfig.IngressRootDomain != "" {
+		root = appConfig.IngressRootDomain
+	} else {
+		return "", "", fmt.Errorf("no ingress domain provided in deploy request or configuration")
 	}
-	return nil
+
+	if name != "" {
+		return util.JoinV(".", name, root), root, nil
+	}
+	return util.JoinV(".", containerName, namespace, root), root, nil
 }
 
-func getIngressAnnotations(opts *DeployIngressOptions) map[string]string {
-	if opts.traefik {
-		return getTraefikHeadersAnnotations(opts)
+// resolveTLSConfig fills in TLSConfig fields left unset by the caller with
+// config.Configuration's TLS defaults, so operators can set org-wide issuers
+// once instead of on every deployment.
+func (ing *ingress) resolveTLSConfig(options *DeployIngressOptions) {
+	if options.TLSConfig.IssuerName == "" {
+		options.TLSConfig.IssuerName = ing.appConfig.DefaultTLSIssuerName
+	}
+	if options.TLSConfig.IssuerKind == "" {
+		options.TLSConfig.IssuerKind = IssuerKind(ing.appConfig.DefaultTLSIssuerKind)
+	}
+	if options.TLSConfig.IssuerKind == "" {
+		options.TLSConfig.IssuerKind = IssuerKindClusterIssuer
+	}
+	if options.TLSConfig.ChallengeType == "" {
+		options.TLSConfig.ChallengeType = ChallengeType(ing.appConfig.DefaultTLSChallengeType)
+	}
+	if options.TLSConfig.ChallengeType == "" {
+		options.TLSConfig.ChallengeType = ChallengeTypeHTTP01
 	}
-	return getNginxHeadersAnnotations(opts)
 }
 
-func getTraefikHeadersAnnotations(opts *DeployIngressOptions) map[string]string {
-	// in default cases traefik uses this as ingress class, however this could be modified
-	const traefikClass = "traefik"
-	annotations := map[string]string{}
-	annotations["kubernetes.io/ingress.class"] = traefikClass
-	if opts.tls {
-		annotations["traefik.ingress.kubernetes.io/router.entrypoints"] = "web,websecure"
-		annotations["acme.cert-manager.io/http01-ingress-class"] = traefikClass
-		annotations["traefik.ingress.kubernetes.io/router.tls"] = fmt.Sprint(true)
-		annotations["kubernetes.io/tls-acme"] = fmt.Sprintf("%v", true)
-		annotations["cert-manager.io/cluster-issuer"] = clusterIssuerDefault
-	} else {
-		annotations["traefik.ingress.kubernetes.io/router.entrypoints"] = "web"
+// buildIngressRules turns the requested ports into one or more IngressRules.
+// A single port keeps the original "/" on host behavior; multiple ports fan
+// out per FanoutStrategy, either as sibling paths on one host ("path") or as
+// one rule per "<port>.<host>" subdomain ("subdomain").
+func buildIngressRules(options *DeployIngressOptions, host string) []*netv1.IngressRuleApplyConfiguration {
+	defaultPath := options.Path
+	if defaultPath == "" {
+		defaultPath = "/"
+	}
+
+	if len(options.ports) == 1 {
+		return []*netv1.IngressRuleApplyConfiguration{
+			ingressRule(host, portPath(options, options.ports[0], defaultPath), options.containerName, options.ports[0], pathType(options)),
+		}
+	}
+
+	if options.FanoutStrategy == "subdomain" {
+		rules := make([]*netv1.IngressRuleApplyConfiguration, 0, len(options.ports))
+		for _, port := range options.ports {
+			portHost := fmt.Sprintf("%d.%s", port, host)
+			if cfg := findPortConfig(options.PortConfigs, port); cfg != nil && cfg.Host != "" {
+				portHost = cfg.Host
+			}
+			rules = append(rules, ingressRule(portHost, portPath(options, port, defaultPath), options.containerName, port, pathType(options)))
+		}
+		return rules
+	}
+
+	paths := make([]*netv1.HTTPIngressPathApplyConfiguration, 0, len(options.ports))
+	for _, port := range options.ports {
+		paths = append(paths, httpIngressPath(portPath(options, port, fmt.Sprintf("/svc-%d/", port)), options.containerName, port, pathType(options)))
+	}
+	return []*netv1.IngressRuleApplyConfiguration{
+		netv1.IngressRule().WithHost(host).WithHTTP(netv1.HTTPIngressRuleValue().WithPaths(paths...)),
+	}
+}
+
+// pathType returns the configured PathType, defaulting to Prefix to match
+// modern Ingress semantics.
+func pathType(options *DeployIngressOptions) v1.PathType {
+	if options.PathType != "" {
+		return options.PathType
+	}
+	return v1.PathTypePrefix
+}
+
+// portPath resolves the path to use for a port, preferring an explicit
+// PortConfigs override over the caller-supplied default.
+func portPath(options *DeployIngressOptions, port int32, def string) string {
+	if cfg := findPortConfig(options.PortConfigs, port); cfg != nil && cfg.Path != "" {
+		return cfg.Path
+	}
+	return def
+}
+
+func ingressRule(host, path, containerName string, port int32, pt v1.PathType) *netv1.IngressRuleApplyConfiguration {
+	return netv1.IngressRule().
+		WithHost(host).
+		WithHTTP(netv1.HTTPIngressRuleValue().WithPaths(httpIngressPath(path, containerName, port, pt)))
+}
+
+func httpIngressPath(path, containerName string, port int32, pt v1.PathType) *netv1.HTTPIngressPathApplyConfiguration {
+	return netv1.HTTPIngressPath().WithPath(path).
+		WithPathType(pt).
+		WithBackend(
+			netv1.IngressBackend().WithService(
+				netv1.IngressServiceBackend().
+					WithName(containerName).
+					WithPort(netv1.ServiceBackendPort().WithNumber(port)),
+			),
+		)
+}
+
+// getTLSConfig builds the IngressTLS entry for a deployment. When wildcard
+// is requested the certificate is issued for "*.<ingressRoot>" rather than
+// the deployment's own host, while the IngressRule keeps routing on the
+// specific host.
+func getTLSConfig(ingressPath, ingressRoot, containerName string, enabled, wildcard bool) *netv1.IngressTLSApplyConfiguration {
+	if !enabled {
+		return nil
 	}
-	return annotations
+
+	tlsHost := ingressPath
+	if wildcard {
+		tlsHost = "*." + ingressRoot
+	}
+
+	return netv1.IngressTLS().
+		WithHosts(tlsHost).
+		WithSecretName(util.JoinV("-", containerName, "tls"))
 }
 
-func getNginxHeadersAnnotations(opts *DeployIngressOptions) map[string]string {
-	annotations := map[string]string{}
-	headers := []string{}
-	if opts.tls {
-		annotations["kubernetes.io/tls-acme"] = fmt.Sprintf("%v", true)
-		annotations["cert-manager.io/cluster-issuer"] = clusterIssuerDefault
+// supportsIngressClassName probes the cluster's server version and reports
+// whether it is new enough (>=1.18) to honor spec.ingressClassName.
+func (ing *ingress) supportsIngressClassName() (bool, error) {
+	clientset, err := ing.client.GetClientSet()
+	if err != nil {
+		return false, err
 	}
-	annotations["kubernetes.io/ingress.class"] = "nginx"
-	// Add Custom Headers to the CORS Allow Header annotation if presents
-	if len(opts.allowedHeaders) > 0 {
-		headers = opts.allowedHeaders
+
+	serverVersion, err := clientset.Discovery().ServerVersion()
+	if err != nil {
+		return false, fmt.Errorf("failed to probe server version: %w", err)
 	}
 
-	if opts.proxyHeaders {
-		extraHeaders := []string{"X-Forwarded-For", "X-Forwarded-Host", "X-Forwarded-Server", "X-Real-IP", "X-Requested-With"}
-		headers = append(headers, extraHeaders...)
+	return versionAtLeast(serverVersion, minIngressClassMajor, minIngressClassMinor), nil
+}
 
-		annotations["nginx.ingress.kubernetes.io/enable-cors"] = "true"
-		annotations["nginx.ingress.kubernetes.io/proxy-buffering"] = "on"
-		annotations["nginx.ingress.kubernetes.io/proxy-buffer-size"] = "256k"
+// ingressClassControllerMatches looks up the named IngressClass and checks
+// its spec.controller against config.Configuration.IngressClassControllerName,
+// so a misconfigured class name (e.g. left over from a migration between
+// controllers) doesn't silently apply. When IngressClassControllerName is
+// unset, the check is skipped and any IngressClass is accepted.
+func (ing *ingress) ingressClassControllerMatches(className string) (bool, error) {
+	if ing.appConfig.IngressClassControllerName == "" {
+		return true, nil
 	}
 
-	// Add header string to cors-allow-headers if presents any value
-	if len(headers) > 0 {
-		annotations["nginx.ingress.kubernetes.io/cors-allow-headers"] = strings.Join(headers, ", ")
+	clientset, err := ing.client.GetClientSet()
+	if err != nil {
+		return false, err
+	}
+
+	class, err := clientset.NetworkingV1().IngressClasses().Get(ing.ctx, className, metav1.GetOptions{})
+	if err != nil {
+		return false, fmt.Errorf("failed to get IngressClass %q: %w", className, err)
+	}
+
+	return class.Spec.Controller == ing.appConfig.IngressClassControllerName, nil
+}
+
+// versionAtLeast compares a Kubernetes discovery version against a
+// major.minor floor, tolerating the "+" suffix Kubernetes appends to
+// Major/Minor for patch releases (e.g. "18+").
+func versionAtLeast(serverVersion *version.Info, major, minor int) bool {
+	gotMajor, err := strconv.Atoi(strings.TrimSuffix(serverVersion.Major, "+"))
+	if err != nil {
+		return false
+	}
+
+	gotMinor, err := strconv.Atoi(strings.TrimSuffix(serverVersion.Minor, "+"))
+	if err != nil {
+		return false
 	}
 
-	if opts.uploadLimit != "" {
-		annotations["nginx.ingress.kubernetes.io/proxy-body-size"] = opts.uploadLimit
+	if gotMajor != major {
+		return gotMajor > major
 	}
-	return annotations
+	return gotMinor >= minor
 }
 
 func (ing *ingress) getIngressClient(namespace string) (networking.IngressInterface, error) {
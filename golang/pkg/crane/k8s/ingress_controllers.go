@@ -0,0 +1,295 @@
+package k8s
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/rs/zerolog/log"
+	netv1 "k8s.io/client-go/applyconfigurations/networking/v1"
+)
+
+// IngressController abstracts the annotation and spec conventions of a
+// specific ingress controller implementation, so deployIngress can target
+// whichever controller a cluster runs without branching on its name.
+type IngressController interface {
+	// Annotations builds the controller-specific annotation set for the
+	// given deployment, including its ingress class, TLS/cert-manager
+	// wiring, and CORS/body-size/proxy-header knobs.
+	Annotations(opts *DeployIngressOptions) map[string]string
+	// ClassName is the controller's conventional kubernetes.io/ingress.class
+	// value. It is not used for spec.ingressClassName, which is only set
+	// from an explicit or configured IngressClassName.
+	ClassName() string
+	// MutateSpec lets the controller adjust the IngressSpecApplyConfiguration
+	// before it is sent to the API server, e.g. to add controller-specific
+	// rule or backend settings. Implementations that need no changes are a
+	// no-op.
+	MutateSpec(spec *netv1.IngressSpecApplyConfiguration)
+}
+
+// ingressControllers is the registry of supported IngressController
+// backends, keyed by the name accepted in DeployIngressOptions.Controller
+// and config.Configuration.IngressController.
+var ingressControllers = map[string]IngressController{
+	"nginx":   nginxController{},
+	"traefik": traefikController{},
+	"contour": contourController{},
+	"haproxy": haproxyController{},
+	"kong":    kongController{},
+}
+
+// resolveIngressController looks up a registered IngressController by name,
+// defaulting to nginx when name is empty or unrecognized.
+func resolveIngressController(name string) IngressController {
+	if controller, ok := ingressControllers[name]; ok {
+		return controller
+	}
+	return nginxController{}
+}
+
+// corsAndProxyHeaders merges the user-supplied allowed headers with the
+// extra forwarding headers implied by proxyHeaders, used by every
+// controller's CORS wiring.
+func corsAndProxyHeaders(opts *DeployIngressOptions) []string {
+	headers := []string{}
+	if len(opts.allowedHeaders) > 0 {
+		headers = opts.allowedHeaders
+	}
+	if opts.proxyHeaders {
+		headers = append(headers, "X-Forwarded-For", "X-Forwarded-Host", "X-Forwarded-Server", "X-Real-IP", "X-Requested-With")
+	}
+	return headers
+}
+
+// warnUnsupportedRewrite logs and drops opts.Rewrite/PermanentRedirect for a
+// controller that has no Ingress-annotation-level way to express either, so
+// the request is dropped loudly instead of being silently ignored.
+func warnUnsupportedRewrite(controllerName string, opts *DeployIngressOptions) {
+	if opts.Rewrite != "" {
+		log.Warn().Str("controller", controllerName).Str("rewrite", opts.Rewrite).
+			Msg("rewrite is not supported via Ingress annotations on this controller, ignoring")
+	}
+	if opts.PermanentRedirect != "" {
+		log.Warn().Str("controller", controllerName).Str("permanentRedirect", opts.PermanentRedirect).
+			Msg("permanent redirect is not supported via Ingress annotations on this controller, ignoring")
+	}
+}
+
+// certManagerIssuerAnnotation picks the cert-manager annotation key and
+// issuer name for the resolved TLSConfig: the key follows IssuerKind, and the
+// name defaults to clusterIssuerDefault when IssuerName is still unset.
+func certManagerIssuerAnnotation(tlsConfig TLSConfig) (key, value string) {
+	name := tlsConfig.IssuerName
+	if name == "" {
+		name = clusterIssuerDefault
+	}
+	if tlsConfig.IssuerKind == IssuerKindIssuer {
+		return "cert-manager.io/issuer", name
+	}
+	return "cert-manager.io/cluster-issuer", name
+}
+
+type nginxController struct{}
+
+func (nginxController) ClassName() string { return "nginx" }
+
+func (nginxController) MutateSpec(*netv1.IngressSpecApplyConfiguration) {}
+
+func (c nginxController) Annotations(opts *DeployIngressOptions) map[string]string {
+	annotations := map[string]string{}
+	if opts.tls {
+		annotations["kubernetes.io/tls-acme"] = fmt.Sprintf("%v", true)
+		key, value := certManagerIssuerAnnotation(opts.TLSConfig)
+		annotations[key] = value
+		// DNS-01 and wildcard support live on the referenced Issuer/
+		// ClusterIssuer's spec.acme.solvers, not on the Ingress: there is no
+		// acme.cert-manager.io annotation that selects a challenge type or
+		// requests a wildcard, so nothing is set here.
+	}
+	annotations["kubernetes.io/ingress.class"] = c.ClassName()
+
+	headers := corsAndProxyHeaders(opts)
+	if opts.proxyHeaders {
+		annotations["nginx.ingress.kubernetes.io/enable-cors"] = "true"
+		annotations["nginx.ingress.kubernetes.io/proxy-buffering"] = "on"
+		annotations["nginx.ingress.kubernetes.io/proxy-buffer-size"] = "256k"
+	}
+	if len(headers) > 0 {
+		annotations["nginx.ingress.kubernetes.io/cors-allow-headers"] = strings.Join(headers, ", ")
+	}
+
+	if opts.uploadLimit != "" {
+		annotations["nginx.ingress.kubernetes.io/proxy-body-size"] = opts.uploadLimit
+	}
+
+	if opts.Rewrite != "" {
+		annotations["nginx.ingress.kubernetes.io/rewrite-target"] = opts.Rewrite
+	}
+	if opts.PermanentRedirect != "" {
+		annotations["nginx.ingress.kubernetes.io/permanent-redirect"] = opts.PermanentRedirect
+	}
+	return annotations
+}
+
+type traefikController struct{}
+
+func (traefikController) ClassName() string { return "traefik" }
+
+func (traefikController) MutateSpec(*netv1.IngressSpecApplyConfiguration) {}
+
+func (c traefikController) Annotations(opts *DeployIngressOptions) map[string]string {
+	annotations := map[string]string{}
+	annotations["kubernetes.io/ingress.class"] = c.ClassName()
+	if opts.tls {
+		annotations["traefik.ingress.kubernetes.io/router.entrypoints"] = "web,websecure"
+		if opts.TLSConfig.ChallengeType != ChallengeTypeDNS01 {
+			annotations["acme.cert-manager.io/http01-ingress-class"] = c.ClassName()
+		}
+		annotations["traefik.ingress.kubernetes.io/router.tls"] = fmt.Sprint(true)
+		annotations["kubernetes.io/tls-acme"] = fmt.Sprintf("%v", true)
+		key, value := certManagerIssuerAnnotation(opts.TLSConfig)
+		annotations[key] = value
+		// DNS-01 and wildcard support live on the referenced Issuer/
+		// ClusterIssuer's spec.acme.solvers, not on the Ingress: there is no
+		// acme.cert-manager.io annotation that selects a challenge type or
+		// requests a wildcard, so nothing is set here.
+	} else {
+		annotations["traefik.ingress.kubernetes.io/router.entrypoints"] = "web"
+	}
+
+	// Each middleware referenced below is provisioned by
+	// applyTraefikMiddlewares before the Ingress is applied, so the
+	// reference always resolves to a real object (CORS headers and body
+	// size have no Traefik annotation equivalent, unlike nginx).
+	middlewares := []string{}
+	if headers := corsAndProxyHeaders(opts); len(headers) > 0 {
+		middlewares = append(middlewares, fmt.Sprintf("%s@kubernetescrd", corsMiddlewareName(opts.containerName)))
+	}
+
+	if validUploadLimit(opts.uploadLimit) {
+		middlewares = append(middlewares, fmt.Sprintf("%s@kubernetescrd", bufferingMiddlewareName(opts.containerName)))
+	}
+
+	if opts.Rewrite != "" || opts.PermanentRedirect != "" {
+		middlewares = append(middlewares, fmt.Sprintf("%s@kubernetescrd", rewriteMiddlewareName(opts.containerName)))
+	}
+
+	if len(middlewares) > 0 {
+		annotations["traefik.ingress.kubernetes.io/router.middlewares"] = strings.Join(middlewares, ",")
+	}
+	return annotations
+}
+
+type contourController struct{}
+
+func (contourController) ClassName() string { return "contour" }
+
+func (contourController) MutateSpec(*netv1.IngressSpecApplyConfiguration) {}
+
+func (c contourController) Annotations(opts *DeployIngressOptions) map[string]string {
+	annotations := map[string]string{}
+	annotations["kubernetes.io/ingress.class"] = c.ClassName()
+	if opts.tls {
+		annotations["ingress.kubernetes.io/force-ssl-redirect"] = "true"
+		key, value := certManagerIssuerAnnotation(opts.TLSConfig)
+		annotations[key] = value
+		// DNS-01 and wildcard support live on the referenced Issuer/
+		// ClusterIssuer's spec.acme.solvers, not on the Ingress: there is no
+		// acme.cert-manager.io annotation that selects a challenge type or
+		// requests a wildcard, so nothing is set here.
+	}
+
+	if headers := corsAndProxyHeaders(opts); len(headers) > 0 {
+		annotations["projectcontour.io/cors-allow-headers"] = strings.Join(headers, ",")
+		annotations["projectcontour.io/cors-allow-origin"] = "*"
+	}
+
+	if opts.uploadLimit != "" {
+		annotations["projectcontour.io/max-request-bytes"] = opts.uploadLimit
+	}
+
+	// Contour only exposes path rewrite/redirect through its HTTPProxy CRD,
+	// not via Ingress annotations, so there is no annotation to set here.
+	warnUnsupportedRewrite(c.ClassName(), opts)
+	return annotations
+}
+
+type haproxyController struct{}
+
+func (haproxyController) ClassName() string { return "haproxy" }
+
+func (haproxyController) MutateSpec(*netv1.IngressSpecApplyConfiguration) {}
+
+func (c haproxyController) Annotations(opts *DeployIngressOptions) map[string]string {
+	annotations := map[string]string{}
+	annotations["kubernetes.io/ingress.class"] = c.ClassName()
+	if opts.tls {
+		annotations["haproxy.org/ssl-redirect"] = "true"
+		key, value := certManagerIssuerAnnotation(opts.TLSConfig)
+		annotations[key] = value
+		// DNS-01 and wildcard support live on the referenced Issuer/
+		// ClusterIssuer's spec.acme.solvers, not on the Ingress: there is no
+		// acme.cert-manager.io annotation that selects a challenge type or
+		// requests a wildcard, so nothing is set here.
+	}
+
+	if headers := corsAndProxyHeaders(opts); len(headers) > 0 {
+		annotations["haproxy.org/cors-enable"] = "true"
+		annotations["haproxy.org/cors-allow-headers"] = strings.Join(headers, ",")
+	}
+
+	if opts.uploadLimit != "" {
+		annotations["haproxy.org/proxy-body-size"] = opts.uploadLimit
+	}
+
+	if opts.Rewrite != "" {
+		annotations["haproxy.org/path-rewrite"] = opts.Rewrite
+	}
+	if opts.PermanentRedirect != "" {
+		annotations["haproxy.org/request-redirect"] = opts.PermanentRedirect
+	}
+	return annotations
+}
+
+type kongController struct{}
+
+func (kongController) ClassName() string { return "kong" }
+
+func (kongController) MutateSpec(*netv1.IngressSpecApplyConfiguration) {}
+
+func (c kongController) Annotations(opts *DeployIngressOptions) map[string]string {
+	annotations := map[string]string{}
+	annotations["kubernetes.io/ingress.class"] = c.ClassName()
+	if opts.tls {
+		annotations["konghq.com/protocols"] = "https"
+		key, value := certManagerIssuerAnnotation(opts.TLSConfig)
+		annotations[key] = value
+		// DNS-01 and wildcard support live on the referenced Issuer/
+		// ClusterIssuer's spec.acme.solvers, not on the Ingress: there is no
+		// acme.cert-manager.io annotation that selects a challenge type or
+		// requests a wildcard, so nothing is set here.
+	} else {
+		annotations["konghq.com/protocols"] = "http"
+	}
+
+	// Each plugin referenced below is provisioned by applyKongPlugins before
+	// the Ingress is applied, so the reference always resolves to a real
+	// KongPlugin object.
+	plugins := []string{}
+	if headers := corsAndProxyHeaders(opts); len(headers) > 0 {
+		plugins = append(plugins, corsPluginName(opts.containerName))
+	}
+
+	if validUploadLimit(opts.uploadLimit) {
+		plugins = append(plugins, bodySizePluginName(opts.containerName))
+	}
+
+	if len(plugins) > 0 {
+		annotations["konghq.com/plugins"] = strings.Join(plugins, ",")
+	}
+
+	// Kong has no declarative redirect/rewrite plugin that maps cleanly onto
+	// a single annotation value, so there is nothing real to set here.
+	warnUnsupportedRewrite(c.ClassName(), opts)
+	return annotations
+}
@@ -0,0 +1,91 @@
+package k8s
+
+import (
+	"encoding/json"
+	"fmt"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/dynamic"
+)
+
+// kongPluginGVR identifies the configuration.konghq.com/v1 KongPlugin CRD
+// that kongController's konghq.com/plugins annotation references. Kong has
+// no annotation-only equivalent of nginx's cors-allow-headers/proxy-body-size,
+// so those knobs are only effective once the KongPlugin objects they name
+// actually exist.
+var kongPluginGVR = schema.GroupVersionResource{Group: "configuration.konghq.com", Version: "v1", Resource: "kongplugins"}
+
+// applyKongPlugins provisions the KongPlugin CRs that kongController.
+// Annotations references via konghq.com/plugins, for whichever of CORS
+// headers and request buffering the deployment requested. It is a no-op for
+// any knob that isn't set.
+func (ing *ingress) applyKongPlugins(options *DeployIngressOptions) error {
+	if headers := corsAndProxyHeaders(options); len(headers) > 0 {
+		if err := ing.applyKongPlugin(options.namespace, corsPluginName(options.containerName), "cors", map[string]interface{}{
+			"headers": toInterfaceSlice(headers),
+			"methods": []interface{}{"GET", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"},
+			"origins": []interface{}{"*"},
+		}); err != nil {
+			return fmt.Errorf("failed to apply cors KongPlugin: %w", err)
+		}
+	}
+
+	if bytes, ok := parseUploadLimitBytes(options.uploadLimit); ok {
+		if err := ing.applyKongPlugin(options.namespace, bodySizePluginName(options.containerName), "request-size-limiting", map[string]interface{}{
+			"allowed_payload_size": bytes / (1024 * 1024),
+			"size_unit":            "megabytes",
+		}); err != nil {
+			return fmt.Errorf("failed to apply request-size-limiting KongPlugin: %w", err)
+		}
+	}
+
+	return nil
+}
+
+func corsPluginName(containerName string) string     { return fmt.Sprintf("%s-cors", containerName) }
+func bodySizePluginName(containerName string) string { return fmt.Sprintf("%s-body-size", containerName) }
+
+// applyKongPlugin server-side applies a single configuration.konghq.com/v1
+// KongPlugin object, mirroring the FieldManager/Force conventions used for
+// the Ingress itself.
+func (ing *ingress) applyKongPlugin(namespace, name, plugin string, config map[string]interface{}) error {
+	client, err := ing.getKongPluginClient(namespace)
+	if err != nil {
+		return err
+	}
+
+	obj := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "configuration.konghq.com/v1",
+		"kind":       "KongPlugin",
+		"metadata": map[string]interface{}{
+			"name":      name,
+			"namespace": namespace,
+		},
+		"plugin": plugin,
+		"config": config,
+	}}
+
+	data, err := json.Marshal(obj.Object)
+	if err != nil {
+		return err
+	}
+
+	force := ing.appConfig.ForceOnConflicts
+	_, err = client.Patch(ing.ctx, name, types.ApplyPatchType, data, metav1.PatchOptions{
+		FieldManager: ing.appConfig.FieldManagerName,
+		Force:        &force,
+	})
+	return err
+}
+
+func (ing *ingress) getKongPluginClient(namespace string) (dynamic.ResourceInterface, error) {
+	dynamicClient, err := ing.client.GetDynamicClient()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get dynamic client: %w", err)
+	}
+
+	return dynamicClient.Resource(kongPluginGVR).Namespace(namespace), nil
+}
@@ -0,0 +1,184 @@
+package k8s
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/rs/zerolog/log"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/dynamic"
+)
+
+// middlewareGVR identifies the traefik.io/v1alpha1 Middleware CRD that
+// traefikController's router.middlewares annotation references. Traefik has
+// no annotation-only equivalent of nginx's cors-allow-headers/proxy-body-size,
+// so those knobs are only effective for the traefik backend once the
+// Middleware objects they name actually exist.
+var middlewareGVR = schema.GroupVersionResource{Group: "traefik.io", Version: "v1alpha1", Resource: "middlewares"}
+
+// applyTraefikMiddlewares provisions the Middleware CRs that
+// traefikController.Annotations references via router.middlewares, for
+// whichever of CORS headers, request buffering, and rewrite/redirect the
+// deployment requested. It is a no-op for any knob that isn't set.
+func (ing *ingress) applyTraefikMiddlewares(options *DeployIngressOptions) error {
+	if headers := corsAndProxyHeaders(options); len(headers) > 0 {
+		if err := ing.applyMiddleware(options.namespace, corsMiddlewareName(options.containerName), map[string]interface{}{
+			"headers": map[string]interface{}{
+				"accessControlAllowHeaders":   toInterfaceSlice(headers),
+				"accessControlAllowMethods":   []interface{}{"GET", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"},
+				"accessControlAllowOriginList": []interface{}{"*"},
+			},
+		}); err != nil {
+			return fmt.Errorf("failed to apply cors-headers middleware: %w", err)
+		}
+	}
+
+	if options.uploadLimit != "" {
+		limit, ok := parseUploadLimitBytes(options.uploadLimit)
+		if !ok {
+			log.Warn().Str("uploadLimit", options.uploadLimit).
+				Msg("uploadLimit is not a valid size, skipping traefik buffering middleware")
+		} else if err := ing.applyMiddleware(options.namespace, bufferingMiddlewareName(options.containerName), map[string]interface{}{
+			"buffering": map[string]interface{}{
+				"maxRequestBodyBytes": limit,
+			},
+		}); err != nil {
+			return fmt.Errorf("failed to apply buffering middleware: %w", err)
+		}
+	}
+
+	if options.Rewrite != "" || options.PermanentRedirect != "" {
+		if err := ing.applyMiddleware(options.namespace, rewriteMiddlewareName(options.containerName), rewriteMiddlewareSpec(options)); err != nil {
+			return fmt.Errorf("failed to apply rewrite middleware: %w", err)
+		}
+	}
+
+	return nil
+}
+
+func corsMiddlewareName(containerName string) string {
+	return fmt.Sprintf("%s-cors-headers", containerName)
+}
+
+func bufferingMiddlewareName(containerName string) string {
+	return fmt.Sprintf("%s-buffering", containerName)
+}
+
+func rewriteMiddlewareName(containerName string) string {
+	return fmt.Sprintf("%s-rewrite", containerName)
+}
+
+// rewriteMiddlewareSpec prefers PermanentRedirect (a RedirectRegex
+// middleware) over Rewrite (a ReplacePathRegex middleware) when both are
+// somehow set, since a redirect makes the rewrite moot.
+func rewriteMiddlewareSpec(options *DeployIngressOptions) map[string]interface{} {
+	if options.PermanentRedirect != "" {
+		return map[string]interface{}{
+			"redirectRegex": map[string]interface{}{
+				"regex":       "^(.*)",
+				"replacement": options.PermanentRedirect,
+				"permanent":   true,
+			},
+		}
+	}
+	return map[string]interface{}{
+		"replacePathRegex": map[string]interface{}{
+			"regex":       "^(.*)",
+			"replacement": options.Rewrite,
+		},
+	}
+}
+
+// parseUploadLimitBytes parses an nginx proxy-body-size-style value ("10m",
+// "512k", "1g", or a bare byte count) into a byte count. It intentionally
+// does not use resource.ParseQuantity: Kubernetes quantity suffixes are
+// SI/binary-prefix based and treat a lowercase "m" as milli (×0.001), which
+// would silently shrink a value meant as megabytes by a factor of a billion.
+func parseUploadLimitBytes(limit string) (int64, bool) {
+	if limit == "" {
+		return 0, false
+	}
+
+	multiplier := int64(1)
+	numeric := limit
+	switch limit[len(limit)-1] {
+	case 'k', 'K':
+		multiplier = 1024
+		numeric = limit[:len(limit)-1]
+	case 'm', 'M':
+		multiplier = 1024 * 1024
+		numeric = limit[:len(limit)-1]
+	case 'g', 'G':
+		multiplier = 1024 * 1024 * 1024
+		numeric = limit[:len(limit)-1]
+	}
+
+	numeric = strings.TrimSpace(numeric)
+	value, err := strconv.ParseInt(numeric, 10, 64)
+	if err != nil || value < 0 {
+		return 0, false
+	}
+	return value * multiplier, true
+}
+
+// validUploadLimit reports whether uploadLimit will be parsed successfully
+// by applyTraefikMiddlewares, so traefikController.Annotations only
+// references the buffering middleware when it is actually going to exist.
+func validUploadLimit(uploadLimit string) bool {
+	_, ok := parseUploadLimitBytes(uploadLimit)
+	return ok
+}
+
+func toInterfaceSlice(values []string) []interface{} {
+	out := make([]interface{}, len(values))
+	for i, v := range values {
+		out[i] = v
+	}
+	return out
+}
+
+// applyMiddleware server-side applies a single traefik.io/v1alpha1
+// Middleware object, mirroring the FieldManager/Force conventions used for
+// the Ingress itself.
+func (ing *ingress) applyMiddleware(namespace, name string, spec map[string]interface{}) error {
+	client, err := ing.getMiddlewareClient(namespace)
+	if err != nil {
+		return err
+	}
+
+	obj := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "traefik.io/v1alpha1",
+		"kind":       "Middleware",
+		"metadata": map[string]interface{}{
+			"name":      name,
+			"namespace": namespace,
+		},
+		"spec": spec,
+	}}
+
+	data, err := json.Marshal(obj.Object)
+	if err != nil {
+		return err
+	}
+
+	force := ing.appConfig.ForceOnConflicts
+	_, err = client.Patch(ing.ctx, name, types.ApplyPatchType, data, metav1.PatchOptions{
+		FieldManager: ing.appConfig.FieldManagerName,
+		Force:        &force,
+	})
+	return err
+}
+
+func (ing *ingress) getMiddlewareClient(namespace string) (dynamic.ResourceInterface, error) {
+	dynamicClient, err := ing.client.GetDynamicClient()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get dynamic client: %w", err)
+	}
+
+	return dynamicClient.Resource(middlewareGVR).Namespace(namespace), nil
+}